@@ -0,0 +1,75 @@
+package molecule
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/richardartoul/molecule/src/codec"
+)
+
+// GroupEach iterates over the fields nested inside a proto2 group and calls
+// fn on each one, the same way MessageEach does for an ordinary message.
+//
+// buffer should be a fresh buffer over the group's raw interior bytes, i.e.
+// codec.NewBuffer(value.Bytes) where value is the Value MessageEach handed
+// fn for the field that introduced the group (recognizable by
+// value.WireType == codec.WireStartGroup). MessageEach has already consumed
+// and validated the group's start/end tags by the time fn sees it, so
+// buffer's contents are exactly the group's nested fields with no group
+// tags of their own to worry about. groupFieldNum is accepted to make call
+// sites self-documenting about which group they're re-entering.
+func GroupEach(buffer *codec.Buffer, groupFieldNum int32, fn MessageEachFn) error {
+	return MessageEach(buffer, fn)
+}
+
+// skipGroup consumes buffer up to and including the WireEndGroup tag that
+// matches groupFieldNum, recursing into any nested groups along the way, and
+// returns the offset of the buffer immediately before that WireEndGroup tag
+// (i.e. the end of the group's interior, not counting the end tag itself).
+func skipGroup(buffer *codec.Buffer, groupFieldNum int32) (int, error) {
+	for !buffer.EOF() {
+		beforeTag := buffer.Mark()
+		fieldNum, wireType, err := buffer.DecodeTagAndWireType()
+		if err == io.EOF {
+			return 0, fmt.Errorf(
+				"skipGroup: reached EOF before WireEndGroup for field %d", groupFieldNum)
+		} else if err != nil {
+			return 0, fmt.Errorf("skipGroup: error decoding tag: %v", err)
+		}
+
+		switch wireType {
+		case codec.WireEndGroup:
+			if fieldNum != groupFieldNum {
+				return 0, fmt.Errorf(
+					"skipGroup: mismatched end group tag: expected field %d, got %d",
+					groupFieldNum, fieldNum)
+			}
+			return beforeTag, nil
+		case codec.WireStartGroup:
+			if _, err := skipGroup(buffer, fieldNum); err != nil {
+				return 0, err
+			}
+		case codec.WireVarint:
+			if _, err := buffer.DecodeVarint(); err != nil {
+				return 0, fmt.Errorf("skipGroup: error decoding varint: %v", err)
+			}
+		case codec.WireFixed32:
+			if _, err := buffer.DecodeFixed32(); err != nil {
+				return 0, fmt.Errorf("skipGroup: error decoding fixed32: %v", err)
+			}
+		case codec.WireFixed64:
+			if _, err := buffer.DecodeFixed64(); err != nil {
+				return 0, fmt.Errorf("skipGroup: error decoding fixed64: %v", err)
+			}
+		case codec.WireBytes:
+			if _, err := buffer.DecodeRawBytes(false); err != nil {
+				return 0, fmt.Errorf("skipGroup: error decoding raw bytes: %v", err)
+			}
+		default:
+			return 0, fmt.Errorf("skipGroup: unknown wireType: %d", wireType)
+		}
+	}
+
+	return 0, fmt.Errorf(
+		"skipGroup: reached end of buffer before WireEndGroup for field %d", groupFieldNum)
+}
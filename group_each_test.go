@@ -0,0 +1,74 @@
+package molecule
+
+import (
+	"testing"
+
+	"github.com/richardartoul/molecule/src/codec"
+)
+
+func TestMessageEachSkipsGroupAndStaysInSync(t *testing.T) {
+	buf := codec.NewBuffer(nil)
+	buf.EncodeTagAndWireType(5, codec.WireStartGroup)
+	buf.EncodeTagAndWireType(1, codec.WireVarint)
+	buf.EncodeVarint(7)
+	buf.EncodeTagAndWireType(5, codec.WireEndGroup)
+	buf.EncodeTagAndWireType(2, codec.WireVarint)
+	buf.EncodeVarint(99)
+
+	var groupValue Value
+	var sawGroup, sawTrailingField bool
+	var trailingValue uint64
+	err := MessageEach(codec.NewBuffer(buf.Bytes()), func(fieldNum int32, value Value) bool {
+		switch fieldNum {
+		case 5:
+			sawGroup = true
+			groupValue = value
+		case 2:
+			sawTrailingField = true
+			trailingValue = value.Number
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatalf("MessageEach returned error: %v", err)
+	}
+	if !sawGroup {
+		t.Fatal("expected to see the group field")
+	}
+	if groupValue.WireType != codec.WireStartGroup {
+		t.Fatalf("expected group field's WireType to be WireStartGroup, got %d", groupValue.WireType)
+	}
+	if !sawTrailingField {
+		t.Fatal("expected the field after the group to still be reached (buffer desynced)")
+	}
+	if trailingValue != 99 {
+		t.Fatalf("expected trailing field to be 99, got %d", trailingValue)
+	}
+
+	var innerValue uint64
+	err = GroupEach(codec.NewBuffer(groupValue.Bytes), 5, func(fieldNum int32, value Value) bool {
+		if fieldNum == 1 {
+			innerValue = value.Number
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatalf("GroupEach returned error: %v", err)
+	}
+	if innerValue != 7 {
+		t.Fatalf("expected group's inner field to be 7, got %d", innerValue)
+	}
+}
+
+func TestMessageEachMismatchedEndGroup(t *testing.T) {
+	buf := codec.NewBuffer(nil)
+	buf.EncodeTagAndWireType(5, codec.WireStartGroup)
+	buf.EncodeTagAndWireType(6, codec.WireEndGroup) // wrong field number
+
+	err := MessageEach(codec.NewBuffer(buf.Bytes()), func(fieldNum int32, value Value) bool {
+		return true
+	})
+	if err == nil {
+		t.Fatal("expected an error for a mismatched end group tag, got nil")
+	}
+}
@@ -0,0 +1,72 @@
+package molecule
+
+import (
+	"testing"
+
+	"github.com/richardartoul/molecule/src/codec"
+)
+
+func TestMessageBuilderRoundTrip(t *testing.T) {
+	mb := NewMessageBuilder()
+	defer mb.Release()
+
+	mb.AppendVarint(1, 42)
+	mb.AppendFixed32(2, 7)
+	mb.AppendFixed64(3, 99)
+	mb.AppendBytes(4, []byte("hello"))
+	mb.AppendSint32(5, -5)
+	mb.AppendMessage(6, func(nested *MessageBuilder) {
+		nested.AppendVarint(1, 1234)
+	})
+
+	got := map[int32]Value{}
+	err := MessageEach(codec.NewBuffer(mb.Bytes()), func(fieldNum int32, value Value) bool {
+		got[fieldNum] = value
+		return true
+	})
+	if err != nil {
+		t.Fatalf("MessageEach returned error: %v", err)
+	}
+
+	if got[1].Number != 42 {
+		t.Errorf("field 1: expected 42, got %d", got[1].Number)
+	}
+	if got[2].Number != 7 {
+		t.Errorf("field 2: expected 7, got %d", got[2].Number)
+	}
+	if got[3].Number != 99 {
+		t.Errorf("field 3: expected 99, got %d", got[3].Number)
+	}
+	if string(got[4].Bytes) != "hello" {
+		t.Errorf("field 4: expected %q, got %q", "hello", got[4].Bytes)
+	}
+
+	var nestedVal uint64
+	err = MessageEach(codec.NewBuffer(got[6].Bytes), func(fieldNum int32, value Value) bool {
+		if fieldNum == 1 {
+			nestedVal = value.Number
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatalf("MessageEach on nested message returned error: %v", err)
+	}
+	if nestedVal != 1234 {
+		t.Errorf("nested field 1: expected 1234, got %d", nestedVal)
+	}
+}
+
+func TestMessageBuilderMarshalAppend(t *testing.T) {
+	mb := NewMessageBuilder()
+	defer mb.Release()
+	mb.AppendVarint(1, 5)
+
+	dst := []byte("prefix:")
+	got := mb.MarshalAppend(dst)
+	if string(got[:len("prefix:")]) != "prefix:" {
+		t.Fatalf("MarshalAppend did not preserve dst prefix: %q", got)
+	}
+	if string(got[len("prefix:"):]) != string(mb.Bytes()) {
+		t.Fatalf("MarshalAppend did not append the built message: %q", got)
+	}
+}
@@ -21,7 +21,7 @@ func MessageEach(buffer *codec.Buffer, fn MessageEachFn) error {
 			return nil
 		}
 
-		value, err := readValueFromBuffer(wireType, buffer)
+		value, err := readValueFromBuffer(fieldNum, wireType, buffer)
 		if err != nil {
 			return fmt.Errorf("MessageEach: error reading value from buffer: %v", err)
 		}
@@ -43,36 +43,15 @@ type PackedRepeatedEachFn func(value Value) bool
 //
 // PackedArrayEach only supports repeated fields encoded using packed encoding.
 func PackedArrayEach(buffer *codec.Buffer, fieldType codec.FieldDescriptorProto_Type, fn PackedRepeatedEachFn) error {
-	var wireType int8
-	switch fieldType {
-	case codec.FieldDescriptorProto_TYPE_INT32,
-		codec.FieldDescriptorProto_TYPE_INT64,
-		codec.FieldDescriptorProto_TYPE_UINT32,
-		codec.FieldDescriptorProto_TYPE_UINT64,
-		codec.FieldDescriptorProto_TYPE_SINT32,
-		codec.FieldDescriptorProto_TYPE_SINT64,
-		codec.FieldDescriptorProto_TYPE_BOOL,
-		codec.FieldDescriptorProto_TYPE_ENUM:
-		wireType = codec.WireVarint
-	case codec.FieldDescriptorProto_TYPE_FIXED64,
-		codec.FieldDescriptorProto_TYPE_SFIXED64,
-		codec.FieldDescriptorProto_TYPE_DOUBLE:
-		wireType = codec.WireFixed64
-	case codec.FieldDescriptorProto_TYPE_FIXED32,
-		codec.FieldDescriptorProto_TYPE_SFIXED32,
-		codec.FieldDescriptorProto_TYPE_FLOAT:
-		wireType = codec.WireFixed32
-	case codec.FieldDescriptorProto_TYPE_STRING,
-		codec.FieldDescriptorProto_TYPE_MESSAGE,
-		codec.FieldDescriptorProto_TYPE_BYTES:
-		wireType = codec.WireBytes
-	default:
-		return fmt.Errorf(
-			"PackedArrayEach: unknown field type: %v", fieldType)
+	wireType, err := wireTypeForFieldType(fieldType)
+	if err != nil {
+		return fmt.Errorf("PackedArrayEach: %v", err)
 	}
 
 	for !buffer.EOF() {
-		value, err := readValueFromBuffer(wireType, buffer)
+		// Packed repeated fields can never themselves contain a group, so
+		// there's no real field number to thread through here.
+		value, err := readValueFromBuffer(0, wireType, buffer)
 		if err != nil {
 			return fmt.Errorf("ArrayEach: error reading value from buffer: %v", err)
 		}
@@ -84,7 +63,7 @@ func PackedArrayEach(buffer *codec.Buffer, fieldType codec.FieldDescriptorProto_
 	return nil
 }
 
-func readValueFromBuffer(wireType int8, buffer *codec.Buffer) (Value, error) {
+func readValueFromBuffer(fieldNum int32, wireType int8, buffer *codec.Buffer) (Value, error) {
 	value := Value{
 		WireType: wireType,
 	}
@@ -118,10 +97,20 @@ func readValueFromBuffer(wireType int8, buffer *codec.Buffer) (Value, error) {
 				"MessageEach: error decoding raw bytes: %v", err)
 		}
 		value.Bytes = b
-	case codec.WireStartGroup, codec.WireEndGroup:
+	case codec.WireStartGroup:
+		// Fully consume the group so the buffer stays in sync for whatever
+		// comes after it, and capture its raw interior (everything between
+		// the start and end tags) so callers can re-enter it with GroupEach
+		// without molecule needing to understand its contents up front.
+		start := buffer.Mark()
+		innerEnd, err := skipGroup(buffer, fieldNum)
+		if err != nil {
+			return Value{}, fmt.Errorf("MessageEach: error skipping group: %v", err)
+		}
+		value.Bytes = buffer.Slice(start, innerEnd)
+	case codec.WireEndGroup:
 		return Value{}, fmt.Errorf(
-			"MessageEach: encountered group wire type: %d. Groups not supported",
-			wireType)
+			"MessageEach: encountered WireEndGroup with no matching WireStartGroup")
 	default:
 		return Value{}, fmt.Errorf(
 			"MessageEach: unknown wireType: %d", wireType)
@@ -129,3 +118,50 @@ func readValueFromBuffer(wireType int8, buffer *codec.Buffer) (Value, error) {
 
 	return value, nil
 }
+
+// wireTypeForFieldType returns the wire type that a field declared with
+// fieldType is expected to be encoded with on the wire.
+func wireTypeForFieldType(fieldType codec.FieldDescriptorProto_Type) (int8, error) {
+	switch fieldType {
+	case codec.FieldDescriptorProto_TYPE_INT32,
+		codec.FieldDescriptorProto_TYPE_INT64,
+		codec.FieldDescriptorProto_TYPE_UINT32,
+		codec.FieldDescriptorProto_TYPE_UINT64,
+		codec.FieldDescriptorProto_TYPE_SINT32,
+		codec.FieldDescriptorProto_TYPE_SINT64,
+		codec.FieldDescriptorProto_TYPE_BOOL,
+		codec.FieldDescriptorProto_TYPE_ENUM:
+		return codec.WireVarint, nil
+	case codec.FieldDescriptorProto_TYPE_FIXED64,
+		codec.FieldDescriptorProto_TYPE_SFIXED64,
+		codec.FieldDescriptorProto_TYPE_DOUBLE:
+		return codec.WireFixed64, nil
+	case codec.FieldDescriptorProto_TYPE_FIXED32,
+		codec.FieldDescriptorProto_TYPE_SFIXED32,
+		codec.FieldDescriptorProto_TYPE_FLOAT:
+		return codec.WireFixed32, nil
+	case codec.FieldDescriptorProto_TYPE_STRING,
+		codec.FieldDescriptorProto_TYPE_MESSAGE,
+		codec.FieldDescriptorProto_TYPE_BYTES:
+		return codec.WireBytes, nil
+	case codec.FieldDescriptorProto_TYPE_GROUP:
+		return codec.WireStartGroup, nil
+	default:
+		return 0, fmt.Errorf("unknown field type: %v", fieldType)
+	}
+}
+
+// isScalarFieldType reports whether fieldType is a scalar numeric type that
+// can be encoded using the packed repeated encoding (as opposed to
+// string/bytes/message/group fields, which are never packed).
+func isScalarFieldType(fieldType codec.FieldDescriptorProto_Type) bool {
+	switch fieldType {
+	case codec.FieldDescriptorProto_TYPE_STRING,
+		codec.FieldDescriptorProto_TYPE_MESSAGE,
+		codec.FieldDescriptorProto_TYPE_BYTES,
+		codec.FieldDescriptorProto_TYPE_GROUP:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,114 @@
+package molecule
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/richardartoul/molecule/src/codec"
+)
+
+// RepeatedEach interprets a single occurrence of a repeated field of the
+// given fieldType, delivering one callback per logical element regardless of
+// whether the producer packed it or not: if firstValue arrived as
+// WireBytes and fieldType is a scalar numeric type, its contents are treated
+// as a packed array and unpacked element-by-element; otherwise firstValue is
+// delivered as-is.
+//
+// It then continues consuming contiguous repeats of fieldNum encoded the
+// same way from restBuffer, so that a packed field split across several
+// consecutive occurrences (which proto3 decoders are required to support) is
+// still delivered as one continuous run of elements. It stops, and rewinds
+// restBuffer, as soon as it sees a tag belonging to a different field number
+// (a field number match is required, not just a wire type match, since two
+// unrelated fields can legitimately share a wire type, e.g. two adjacent
+// int32s are both WireVarint).
+func RepeatedEach(fieldNum int32, fieldType codec.FieldDescriptorProto_Type, firstValue Value, restBuffer *codec.Buffer, fn PackedRepeatedEachFn) error {
+	deliver := func(value Value) (bool, error) {
+		if value.WireType == codec.WireBytes && isScalarFieldType(fieldType) {
+			shouldContinue := true
+			err := PackedArrayEach(codec.NewBuffer(value.Bytes), fieldType, func(elem Value) bool {
+				shouldContinue = fn(elem)
+				return shouldContinue
+			})
+			return shouldContinue, err
+		}
+		return fn(value), nil
+	}
+
+	shouldContinue, err := deliver(firstValue)
+	if err != nil {
+		return fmt.Errorf("RepeatedEach: %v", err)
+	}
+	if !shouldContinue {
+		return nil
+	}
+
+	for {
+		mark := restBuffer.Mark()
+		if restBuffer.EOF() {
+			return nil
+		}
+
+		nextFieldNum, wireType, err := restBuffer.DecodeTagAndWireType()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("RepeatedEach: error decoding tag: %v", err)
+		}
+		if nextFieldNum != fieldNum || wireType != firstValue.WireType {
+			restBuffer.Rewind(mark)
+			return nil
+		}
+
+		value, err := readValueFromBuffer(nextFieldNum, wireType, restBuffer)
+		if err != nil {
+			return fmt.Errorf("RepeatedEach: error reading value from buffer: %v", err)
+		}
+
+		shouldContinue, err = deliver(value)
+		if err != nil {
+			return fmt.Errorf("RepeatedEach: %v", err)
+		}
+		if !shouldContinue {
+			return nil
+		}
+	}
+}
+
+// MessageEachRepeated iterates over the top-level fields of the message
+// stored in buffer like MessageEach, but hands each run of consecutive
+// occurrences of targetFieldNum to one RepeatedEach call so fn is invoked once
+// per logical element of that repeated field, however the producer chose to
+// encode it (packed or unpacked, in one occurrence or several).
+func MessageEachRepeated(
+	buffer *codec.Buffer,
+	targetFieldNum int32,
+	fieldType codec.FieldDescriptorProto_Type,
+	fn PackedRepeatedEachFn,
+) error {
+	var iterErr error
+	err := MessageEach(buffer, func(fieldNum int32, value Value) bool {
+		if fieldNum != targetFieldNum {
+			return true
+		}
+
+		stopped := false
+		wrapped := func(elem Value) bool {
+			shouldContinue := fn(elem)
+			if !shouldContinue {
+				stopped = true
+			}
+			return shouldContinue
+		}
+
+		if err := RepeatedEach(targetFieldNum, fieldType, value, buffer, wrapped); err != nil {
+			iterErr = fmt.Errorf("MessageEachRepeated: %v", err)
+			return false
+		}
+		return !stopped
+	})
+	if iterErr != nil {
+		return iterErr
+	}
+	return err
+}
@@ -0,0 +1,71 @@
+package molecule
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/richardartoul/molecule/src/codec"
+)
+
+// TestMessageEachRepeatedDoesNotSwallowAdjacentField is a regression test:
+// field 4 follows field 3 and shares the same wire type (WireVarint), and
+// previously would have been misinterpreted as another element of field 3's
+// repeated run.
+func TestMessageEachRepeatedDoesNotSwallowAdjacentField(t *testing.T) {
+	b := codec.NewBuffer(nil)
+	b.EncodeTagAndWireType(3, codec.WireVarint)
+	b.EncodeVarint(1)
+	b.EncodeTagAndWireType(3, codec.WireVarint)
+	b.EncodeVarint(2)
+	b.EncodeTagAndWireType(4, codec.WireVarint)
+	b.EncodeVarint(99)
+
+	var got []uint64
+	err := MessageEachRepeated(codec.NewBuffer(b.Bytes()), 3, codec.FieldDescriptorProto_TYPE_INT32, func(value Value) bool {
+		got = append(got, value.Number)
+		return true
+	})
+	if err != nil {
+		t.Fatalf("MessageEachRepeated returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, []uint64{1, 2}) {
+		t.Fatalf("expected [1 2], got %v (field 4 was likely swallowed)", got)
+	}
+
+	var gotField4 uint64
+	err = MessageEach(codec.NewBuffer(b.Bytes()), func(fieldNum int32, value Value) bool {
+		if fieldNum == 4 {
+			gotField4 = value.Number
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatalf("MessageEach returned error: %v", err)
+	}
+	if gotField4 != 99 {
+		t.Fatalf("expected field 4 to be 99, got %d", gotField4)
+	}
+}
+
+func TestMessageEachRepeatedPacked(t *testing.T) {
+	packed := codec.NewBuffer(nil)
+	packed.EncodeVarint(10)
+	packed.EncodeVarint(20)
+	packed.EncodeVarint(30)
+
+	b := codec.NewBuffer(nil)
+	b.EncodeTagAndWireType(7, codec.WireBytes)
+	b.EncodeRawBytes(packed.Bytes())
+
+	var got []uint64
+	err := MessageEachRepeated(codec.NewBuffer(b.Bytes()), 7, codec.FieldDescriptorProto_TYPE_INT32, func(value Value) bool {
+		got = append(got, value.Number)
+		return true
+	})
+	if err != nil {
+		t.Fatalf("MessageEachRepeated returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, []uint64{10, 20, 30}) {
+		t.Fatalf("expected [10 20 30], got %v", got)
+	}
+}
@@ -0,0 +1,85 @@
+package codec
+
+import (
+	"encoding/binary"
+)
+
+// EncodeTagAndWireType encodes the provided field number and wire type into
+// a single varint tag (as specified by the protobuf wire format) and appends
+// it to the buffer.
+func (b *Buffer) EncodeTagAndWireType(fieldNum int32, wireType int8) {
+	tag := uint64(fieldNum)<<3 | uint64(wireType)
+	b.EncodeVarint(tag)
+}
+
+// EncodeVarint appends v to the buffer using the protobuf base-128 varint
+// encoding.
+func (b *Buffer) EncodeVarint(v uint64) {
+	for v >= 0x80 {
+		b.buf = append(b.buf, byte(v)|0x80)
+		v >>= 7
+	}
+	b.buf = append(b.buf, byte(v))
+}
+
+// EncodeFixed32 appends v to the buffer as 4 little-endian bytes.
+func (b *Buffer) EncodeFixed32(v uint32) {
+	var scratch [4]byte
+	binary.LittleEndian.PutUint32(scratch[:], v)
+	b.buf = append(b.buf, scratch[:]...)
+}
+
+// EncodeFixed64 appends v to the buffer as 8 little-endian bytes.
+func (b *Buffer) EncodeFixed64(v uint64) {
+	var scratch [8]byte
+	binary.LittleEndian.PutUint64(scratch[:], v)
+	b.buf = append(b.buf, scratch[:]...)
+}
+
+// EncodeRawBytes encodes the length of bytes as a varint followed by the raw
+// contents of bytes itself, matching the wire format for string/bytes/message
+// fields.
+func (b *Buffer) EncodeRawBytes(bytes []byte) {
+	b.EncodeVarint(uint64(len(bytes)))
+	b.buf = append(b.buf, bytes...)
+}
+
+// EncodeRaw appends bytes to the buffer verbatim, with no length prefix or
+// other framing. It's used to replay a field's value exactly as it was
+// captured off the wire (e.g. by WriteUnknown), as opposed to EncodeRawBytes,
+// which encodes a length-delimited field from scratch.
+func (b *Buffer) EncodeRaw(bytes []byte) {
+	b.buf = append(b.buf, bytes...)
+}
+
+// EncodeZigZag32 zig-zag encodes v, the same encoding used for sint32 fields,
+// and appends the result to the buffer as a varint.
+func (b *Buffer) EncodeZigZag32(v int32) {
+	b.EncodeVarint(uint64(uint32((v << 1) ^ (v >> 31))))
+}
+
+// EncodeZigZag64 zig-zag encodes v, the same encoding used for sint64 fields,
+// and appends the result to the buffer as a varint.
+func (b *Buffer) EncodeZigZag64(v int64) {
+	b.EncodeVarint(uint64((v << 1) ^ (v >> 63)))
+}
+
+// Bytes returns the contents that have been encoded into the buffer so far.
+// The returned slice is only valid until the next call to Reset.
+func (b *Buffer) Bytes() []byte {
+	return b.buf
+}
+
+// Reset discards any contents previously encoded into the buffer and
+// reinitializes it for decoding (or encoding) buf. Passing a nil buf
+// repurposes the buffer's existing storage for a fresh round of encoding,
+// which is the pattern MessageBuilder's pool relies on to stay
+// allocation-free across uses.
+func (b *Buffer) Reset(buf []byte) {
+	if buf != nil {
+		b.buf = buf
+	} else {
+		b.buf = b.buf[:0]
+	}
+	b.offset = 0
+}
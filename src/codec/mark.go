@@ -0,0 +1,22 @@
+package codec
+
+// Mark returns an opaque snapshot of the buffer's current read position that
+// can later be passed to Rewind to resume decoding from this exact point.
+func (b *Buffer) Mark() int {
+	return b.offset
+}
+
+// Rewind resets the buffer's read position to a mark previously obtained
+// from Mark. It's used by callers that need to peek at the next field's tag
+// to decide how to handle it, then put the buffer back if the peek didn't
+// pan out.
+func (b *Buffer) Rewind(mark int) {
+	b.offset = mark
+}
+
+// Slice returns the raw bytes between two marks previously obtained from
+// Mark, without copying. It's used to capture the exact wire bytes spanned
+// by a region of the buffer, e.g. the interior of a group.
+func (b *Buffer) Slice(start, end int) []byte {
+	return b.buf[start:end]
+}
@@ -0,0 +1,106 @@
+package molecule
+
+import (
+	"fmt"
+
+	"github.com/richardartoul/molecule/src/codec"
+)
+
+// criticalFieldNumberThreshold is the field number at or above which StrictMessageEach
+// treats an undeclared field as a harmless extension rather than a critical
+// unknown field. It mirrors the reserved range protobuf itself carves out for
+// extensions (field numbers 19000-19999 aside), following the same
+// unknown-field classification cosmos-sdk's unknownproto package uses.
+const criticalFieldNumberThreshold = 1 << 29
+
+// StrictMessageEach behaves like MessageEach, except every incoming field
+// number must be declared in md. A field that is present on the wire but not
+// declared in md is considered "critical" (and causes StrictMessageEach to
+// return an error) unless its field number is >= criticalFieldNumberThreshold,
+// in which case it's assumed to be a harmless, not-yet-understood extension
+// and is passed to fn like any other field.
+//
+// StrictMessageEach also verifies that every field md marks as required was
+// actually present on the wire, and recurses into nested messages using
+// their own descriptor so the same validation applies transitively.
+//
+// This is intended for security-sensitive decoding paths (e.g. transaction
+// verification) where silently accepting unknown fields can open the door to
+// signature-malleability style bugs.
+func StrictMessageEach(buffer *codec.Buffer, md *codec.DescriptorProto, fn MessageEachFn) error {
+	fieldsByNumber := make(map[int32]*codec.FieldDescriptorProto, len(md.Field))
+	for _, fd := range md.Field {
+		fieldsByNumber[fd.Number] = fd
+	}
+
+	seen := make(map[int32]bool, len(md.Field))
+	var iterErr error
+	err := MessageEach(buffer, func(fieldNum int32, value Value) bool {
+		seen[fieldNum] = true
+
+		fd, ok := fieldsByNumber[fieldNum]
+		if !ok {
+			if fieldNum < criticalFieldNumberThreshold {
+				iterErr = fmt.Errorf(
+					"StrictMessageEach: encountered undeclared critical field %d in message %q",
+					fieldNum, md.Name)
+				return false
+			}
+			return fn(fieldNum, value)
+		}
+
+		expectedWireType, err := wireTypeForFieldType(fd.Type)
+		if err != nil {
+			iterErr = fmt.Errorf("StrictMessageEach: %v", err)
+			return false
+		}
+
+		if fd.Label == codec.FieldDescriptorProto_LABEL_REPEATED &&
+			value.WireType == codec.WireBytes && isScalarFieldType(fd.Type) {
+			shouldContinue := true
+			err := PackedArrayEach(codec.NewBuffer(value.Bytes), fd.Type, func(elem Value) bool {
+				shouldContinue = fn(fieldNum, elem)
+				return shouldContinue
+			})
+			if err != nil {
+				iterErr = fmt.Errorf("StrictMessageEach: error reading packed field %d: %v", fieldNum, err)
+				return false
+			}
+			return shouldContinue
+		}
+
+		if value.WireType != expectedWireType {
+			iterErr = fmt.Errorf(
+				"StrictMessageEach: field %d (%s) declared type %v expects wire type %d, got %d",
+				fieldNum, fd.Name, fd.Type, expectedWireType, value.WireType)
+			return false
+		}
+
+		if fd.Type == codec.FieldDescriptorProto_TYPE_MESSAGE && fd.MessageType != nil {
+			if err := StrictMessageEach(codec.NewBuffer(value.Bytes), fd.MessageType, func(int32, Value) bool {
+				return true
+			}); err != nil {
+				iterErr = err
+				return false
+			}
+		}
+
+		return fn(fieldNum, value)
+	})
+	if iterErr != nil {
+		return iterErr
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, fd := range md.Field {
+		if fd.Label == codec.FieldDescriptorProto_LABEL_REQUIRED && !seen[fd.Number] {
+			return fmt.Errorf(
+				"StrictMessageEach: message %q missing required field %d (%s)",
+				md.Name, fd.Number, fd.Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package molecule
+
+import (
+	"testing"
+
+	"github.com/richardartoul/molecule/src/codec"
+)
+
+func testDescriptor() *codec.DescriptorProto {
+	return &codec.DescriptorProto{
+		Name: "TestMessage",
+		Field: []*codec.FieldDescriptorProto{
+			{Name: "id", Number: 1, Type: codec.FieldDescriptorProto_TYPE_INT64, Label: codec.FieldDescriptorProto_LABEL_REQUIRED},
+			{Name: "name", Number: 2, Type: codec.FieldDescriptorProto_TYPE_STRING},
+		},
+	}
+}
+
+func TestStrictMessageEachRejectsUndeclaredCriticalField(t *testing.T) {
+	b := codec.NewBuffer(nil)
+	b.EncodeTagAndWireType(1, codec.WireVarint)
+	b.EncodeVarint(1)
+	b.EncodeTagAndWireType(99, codec.WireVarint) // not declared, below the extension threshold
+	b.EncodeVarint(1)
+
+	err := StrictMessageEach(codec.NewBuffer(b.Bytes()), testDescriptor(), func(fieldNum int32, value Value) bool {
+		return true
+	})
+	if err == nil {
+		t.Fatal("expected an error for an undeclared critical field, got nil")
+	}
+}
+
+func TestStrictMessageEachAllowsUndeclaredExtensionField(t *testing.T) {
+	b := codec.NewBuffer(nil)
+	b.EncodeTagAndWireType(1, codec.WireVarint)
+	b.EncodeVarint(1)
+	b.EncodeTagAndWireType(criticalFieldNumberThreshold, codec.WireVarint)
+	b.EncodeVarint(1)
+
+	err := StrictMessageEach(codec.NewBuffer(b.Bytes()), testDescriptor(), func(fieldNum int32, value Value) bool {
+		return true
+	})
+	if err != nil {
+		t.Fatalf("expected no error for an undeclared field above the extension threshold, got: %v", err)
+	}
+}
+
+func TestStrictMessageEachMissingRequiredField(t *testing.T) {
+	b := codec.NewBuffer(nil)
+	b.EncodeTagAndWireType(2, codec.WireBytes)
+	b.EncodeRawBytes([]byte("hi"))
+
+	err := StrictMessageEach(codec.NewBuffer(b.Bytes()), testDescriptor(), func(fieldNum int32, value Value) bool {
+		return true
+	})
+	if err == nil {
+		t.Fatal("expected an error for a missing required field, got nil")
+	}
+}
+
+func TestStrictMessageEachPackedRepeatedField(t *testing.T) {
+	md := &codec.DescriptorProto{
+		Name: "TestMessageWithRepeated",
+		Field: []*codec.FieldDescriptorProto{
+			{Name: "id", Number: 1, Type: codec.FieldDescriptorProto_TYPE_INT64, Label: codec.FieldDescriptorProto_LABEL_REQUIRED},
+			{Name: "nums", Number: 3, Type: codec.FieldDescriptorProto_TYPE_INT32, Label: codec.FieldDescriptorProto_LABEL_REPEATED},
+		},
+	}
+
+	packed := codec.NewBuffer(nil)
+	packed.EncodeVarint(1)
+	packed.EncodeVarint(2)
+	packed.EncodeVarint(3)
+
+	b := codec.NewBuffer(nil)
+	b.EncodeTagAndWireType(1, codec.WireVarint)
+	b.EncodeVarint(1)
+	b.EncodeTagAndWireType(3, codec.WireBytes)
+	b.EncodeRawBytes(packed.Bytes())
+
+	var got []uint64
+	err := StrictMessageEach(codec.NewBuffer(b.Bytes()), md, func(fieldNum int32, value Value) bool {
+		if fieldNum == 3 {
+			got = append(got, value.Number)
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatalf("expected a legitimately packed repeated field to be accepted, got error: %v", err)
+	}
+	if len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Fatalf("expected packed elements [1 2 3], got %v", got)
+	}
+}
+
+func TestStrictMessageEachWireTypeMismatch(t *testing.T) {
+	b := codec.NewBuffer(nil)
+	b.EncodeTagAndWireType(1, codec.WireVarint)
+	b.EncodeVarint(1)
+	b.EncodeTagAndWireType(2, codec.WireVarint) // field 2 is declared TYPE_STRING, expects WireBytes
+	b.EncodeVarint(5)
+
+	err := StrictMessageEach(codec.NewBuffer(b.Bytes()), testDescriptor(), func(fieldNum int32, value Value) bool {
+		return true
+	})
+	if err == nil {
+		t.Fatal("expected an error for a declared field encoded with the wrong wire type, got nil")
+	}
+}
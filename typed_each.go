@@ -0,0 +1,126 @@
+package molecule
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/richardartoul/molecule/src/codec"
+)
+
+// TypedValue is a Value that has already been validated against, and
+// interpreted according to, a FieldDescriptorProto's declared type.
+type TypedValue struct {
+	Value
+	// ZigZag holds the zig-zag decoded value for SINT32/SINT64 fields. It is
+	// meaningless for every other field type; use Value.Number instead.
+	ZigZag int64
+}
+
+// MessageEachTypedFn is called for each top-level field in a message passed
+// to MessageEachTyped, along with the FieldDescriptorProto that describes it.
+type MessageEachTypedFn func(fd *codec.FieldDescriptorProto, typed TypedValue) bool
+
+// MessageEachTyped iterates over each top-level field in the message stored
+// in buffer the same way MessageEach does, except it looks up each incoming
+// field number in md and hands fn a TypedValue instead of a raw Value.
+//
+// MessageEachTyped validates that the wire type of every incoming field
+// matches the type declared for it in md, zig-zag decodes SINT32/SINT64
+// fields automatically, and transparently unpacks repeated scalar fields
+// regardless of whether the producer encoded them packed or unpacked: fn is
+// called once per element either way. Fields present on the wire but not
+// declared in md are silently ignored, the same way an unrecognized proto3
+// field would be.
+//
+// A field declared TYPE_GROUP or TYPE_MESSAGE is handed to fn as-is, with
+// TypedValue.Bytes holding its raw contents; MessageEachTyped does not
+// recurse into it automatically. Callers that need to descend into a group
+// should re-enter with GroupEach (or MessageEachTyped again, for a nested
+// message) using that slice.
+//
+// md is expected to be a long-lived, effectively-static descriptor (e.g. one
+// built once at init time and reused for every message of that type):
+// MessageEachTyped caches the field-number index it builds from md, keyed by
+// md's pointer identity, for the life of the process. Passing a freshly
+// allocated *codec.DescriptorProto on every call will leak one cache entry
+// per call.
+func MessageEachTyped(buffer *codec.Buffer, md *codec.DescriptorProto, fn MessageEachTypedFn) error {
+	fieldsByNumber := fieldsByNumberFor(md)
+
+	var iterErr error
+	err := MessageEach(buffer, func(fieldNum int32, value Value) bool {
+		fd, ok := fieldsByNumber[fieldNum]
+		if !ok {
+			return true
+		}
+
+		expectedWireType, err := wireTypeForFieldType(fd.Type)
+		if err != nil {
+			iterErr = fmt.Errorf("MessageEachTyped: %v", err)
+			return false
+		}
+
+		if fd.Label == codec.FieldDescriptorProto_LABEL_REPEATED &&
+			value.WireType == codec.WireBytes && isScalarFieldType(fd.Type) {
+			shouldContinue := true
+			err := PackedArrayEach(codec.NewBuffer(value.Bytes), fd.Type, func(elem Value) bool {
+				shouldContinue = fn(fd, typedValueFor(fd, elem))
+				return shouldContinue
+			})
+			if err != nil {
+				iterErr = fmt.Errorf("MessageEachTyped: error reading packed field %d: %v", fieldNum, err)
+				return false
+			}
+			return shouldContinue
+		}
+
+		if value.WireType != expectedWireType {
+			iterErr = fmt.Errorf(
+				"MessageEachTyped: field %d declared type %v expects wire type %d, got %d",
+				fieldNum, fd.Type, expectedWireType, value.WireType)
+			return false
+		}
+
+		return fn(fd, typedValueFor(fd, value))
+	})
+	if iterErr != nil {
+		return iterErr
+	}
+	return err
+}
+
+// fieldIndexCache memoizes the field-number index built for each descriptor
+// passed to MessageEachTyped, keyed by the descriptor's identity. Without it,
+// a call per message (MessageEachTyped's intended hot-path use) would
+// rebuild the same map from scratch every time, reintroducing exactly the
+// kind of per-message allocation this library exists to avoid.
+var fieldIndexCache sync.Map // map[*codec.DescriptorProto]map[int32]*codec.FieldDescriptorProto
+
+func fieldsByNumberFor(md *codec.DescriptorProto) map[int32]*codec.FieldDescriptorProto {
+	if cached, ok := fieldIndexCache.Load(md); ok {
+		return cached.(map[int32]*codec.FieldDescriptorProto)
+	}
+
+	fieldsByNumber := make(map[int32]*codec.FieldDescriptorProto, len(md.Field))
+	for _, fd := range md.Field {
+		fieldsByNumber[fd.Number] = fd
+	}
+
+	actual, _ := fieldIndexCache.LoadOrStore(md, fieldsByNumber)
+	return actual.(map[int32]*codec.FieldDescriptorProto)
+}
+
+// typedValueFor wraps value as a TypedValue, zig-zag decoding it first if
+// fd declares it as a SINT32 or SINT64 field.
+func typedValueFor(fd *codec.FieldDescriptorProto, value Value) TypedValue {
+	typed := TypedValue{Value: value}
+	switch fd.Type {
+	case codec.FieldDescriptorProto_TYPE_SINT32:
+		n := uint32(value.Number)
+		typed.ZigZag = int64(int32(n>>1) ^ -int32(n&1))
+	case codec.FieldDescriptorProto_TYPE_SINT64:
+		n := value.Number
+		typed.ZigZag = int64(n>>1) ^ -int64(n&1)
+	}
+	return typed
+}
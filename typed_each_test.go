@@ -0,0 +1,112 @@
+package molecule
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/richardartoul/molecule/src/codec"
+)
+
+func TestMessageEachTypedPackedAndUnpacked(t *testing.T) {
+	md := &codec.DescriptorProto{
+		Name: "TestMessage",
+		Field: []*codec.FieldDescriptorProto{
+			{Name: "nums", Number: 1, Type: codec.FieldDescriptorProto_TYPE_INT32, Label: codec.FieldDescriptorProto_LABEL_REPEATED},
+			{Name: "tag", Number: 2, Type: codec.FieldDescriptorProto_TYPE_SINT32},
+		},
+	}
+
+	cases := []struct {
+		name string
+		buf  func() *codec.Buffer
+	}{
+		{
+			name: "unpacked",
+			buf: func() *codec.Buffer {
+				b := codec.NewBuffer(nil)
+				b.EncodeTagAndWireType(1, codec.WireVarint)
+				b.EncodeVarint(1)
+				b.EncodeTagAndWireType(1, codec.WireVarint)
+				b.EncodeVarint(2)
+				b.EncodeTagAndWireType(1, codec.WireVarint)
+				b.EncodeVarint(3)
+				return b
+			},
+		},
+		{
+			name: "packed",
+			buf: func() *codec.Buffer {
+				packed := codec.NewBuffer(nil)
+				packed.EncodeVarint(1)
+				packed.EncodeVarint(2)
+				packed.EncodeVarint(3)
+
+				b := codec.NewBuffer(nil)
+				b.EncodeTagAndWireType(1, codec.WireBytes)
+				b.EncodeRawBytes(packed.Bytes())
+				return b
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []uint64
+			err := MessageEachTyped(codec.NewBuffer(tc.buf().Bytes()), md, func(fd *codec.FieldDescriptorProto, typed TypedValue) bool {
+				got = append(got, typed.Number)
+				return true
+			})
+			if err != nil {
+				t.Fatalf("MessageEachTyped returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, []uint64{1, 2, 3}) {
+				t.Fatalf("expected [1 2 3], got %v", got)
+			}
+		})
+	}
+}
+
+func TestMessageEachTypedZigZag(t *testing.T) {
+	md := &codec.DescriptorProto{
+		Name: "TestMessage",
+		Field: []*codec.FieldDescriptorProto{
+			{Name: "signed", Number: 1, Type: codec.FieldDescriptorProto_TYPE_SINT32},
+		},
+	}
+
+	b := codec.NewBuffer(nil)
+	b.EncodeTagAndWireType(1, codec.WireVarint)
+	b.EncodeZigZag32(-5)
+
+	var got int64
+	err := MessageEachTyped(codec.NewBuffer(b.Bytes()), md, func(fd *codec.FieldDescriptorProto, typed TypedValue) bool {
+		got = typed.ZigZag
+		return true
+	})
+	if err != nil {
+		t.Fatalf("MessageEachTyped returned error: %v", err)
+	}
+	if got != -5 {
+		t.Fatalf("expected zig-zag decoded value -5, got %d", got)
+	}
+}
+
+func TestMessageEachTypedWireTypeMismatch(t *testing.T) {
+	md := &codec.DescriptorProto{
+		Name: "TestMessage",
+		Field: []*codec.FieldDescriptorProto{
+			{Name: "msg", Number: 1, Type: codec.FieldDescriptorProto_TYPE_MESSAGE},
+		},
+	}
+
+	b := codec.NewBuffer(nil)
+	b.EncodeTagAndWireType(1, codec.WireVarint)
+	b.EncodeVarint(123)
+
+	err := MessageEachTyped(codec.NewBuffer(b.Bytes()), md, func(fd *codec.FieldDescriptorProto, typed TypedValue) bool {
+		return true
+	})
+	if err == nil {
+		t.Fatal("expected an error for a wire type that doesn't match the declared field type, got nil")
+	}
+}
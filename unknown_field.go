@@ -0,0 +1,96 @@
+package molecule
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/richardartoul/molecule/src/codec"
+)
+
+// FieldAction is returned by a MessageEachWithUnknownsFn to tell
+// MessageEachWithUnknowns how to proceed after it has processed a field.
+type FieldAction int
+
+const (
+	// Continue tells MessageEachWithUnknowns to move on to the next field,
+	// the same as returning true from a MessageEachFn.
+	Continue FieldAction = iota
+	// Stop tells MessageEachWithUnknowns to stop iterating immediately, the
+	// same as returning false from a MessageEachFn.
+	Stop
+	// SkipField tells MessageEachWithUnknowns that the field was not
+	// recognized by the caller and should be captured as an UnknownField so
+	// it can be re-emitted later via WriteUnknown.
+	SkipField
+)
+
+// MessageEachWithUnknownsFn is like MessageEachFn except it returns a
+// FieldAction instead of a bool, so a field the caller doesn't recognize can
+// be marked with SkipField instead of just being dropped.
+type MessageEachWithUnknownsFn func(fieldNum int32, value Value) FieldAction
+
+// UnknownField captures a single field that MessageEachWithUnknowns's caller
+// marked with SkipField, preserving everything needed to re-emit it
+// byte-for-byte with WriteUnknown.
+type UnknownField struct {
+	FieldNum int32
+	WireType int8
+	Value    Value
+	// RawBytes is the field's value exactly as it appeared on the wire
+	// (length prefix included for length-delimited fields, closing
+	// WireEndGroup tag included for group fields). It's
+	// captured separately from Value because Value.Number is a decoded
+	// varint/fixed-width integer, and the wire format permits encoding the
+	// same integer with extra, non-canonical padding; re-deriving bytes from
+	// Value.Number would silently drop that padding instead of round-tripping
+	// it untouched.
+	RawBytes []byte
+}
+
+// MessageEachWithUnknowns behaves like MessageEach, except fn returns a
+// FieldAction instead of a bool. Every field for which fn returns SkipField
+// is recorded, in the order it was encountered, and returned to the caller
+// once iteration finishes.
+//
+// This is intended for transparent rewrite pipelines: parse a message,
+// mutate the handful of fields the caller cares about, and re-emit every
+// other field untouched by passing each returned UnknownField to
+// WriteUnknown in order.
+func MessageEachWithUnknowns(buffer *codec.Buffer, fn MessageEachWithUnknownsFn) ([]UnknownField, error) {
+	var unknown []UnknownField
+	for !buffer.EOF() {
+		fieldNum, wireType, err := buffer.DecodeTagAndWireType()
+		if err == io.EOF {
+			return unknown, nil
+		}
+
+		valueStart := buffer.Mark()
+		value, err := readValueFromBuffer(fieldNum, wireType, buffer)
+		if err != nil {
+			return nil, fmt.Errorf("MessageEachWithUnknowns: error reading value from buffer: %v", err)
+		}
+
+		switch fn(fieldNum, value) {
+		case Stop:
+			return unknown, nil
+		case SkipField:
+			unknown = append(unknown, UnknownField{
+				FieldNum: fieldNum,
+				WireType: wireType,
+				Value:    value,
+				RawBytes: buffer.Slice(valueStart, buffer.Mark()),
+			})
+		}
+	}
+	return unknown, nil
+}
+
+// WriteUnknown re-emits field onto buffer using its original field number,
+// wire type, and RawBytes, so the resulting bytes are indistinguishable from
+// the field as it was originally received by MessageEachWithUnknowns,
+// padded varints and all.
+func WriteUnknown(buffer *codec.Buffer, field UnknownField) error {
+	buffer.EncodeTagAndWireType(field.FieldNum, field.WireType)
+	buffer.EncodeRaw(field.RawBytes)
+	return nil
+}
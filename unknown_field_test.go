@@ -0,0 +1,71 @@
+package molecule
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/richardartoul/molecule/src/codec"
+)
+
+// TestMessageEachWithUnknownsPreservesPadding ensures that a non-canonical
+// (padded) varint on an unrecognized field round-trips byte-for-byte through
+// MessageEachWithUnknowns + WriteUnknown, rather than being re-encoded from
+// its decoded value (which would silently normalize away the padding).
+func TestMessageEachWithUnknownsPreservesPadding(t *testing.T) {
+	// Field 1, varint, value 1, encoded as a zero-padded 2-byte varint
+	// instead of the canonical single byte.
+	paddedVarintField := []byte{0x08, 0x81, 0x00}
+	// Field 2, varint, value 42, recognized and handled by the caller.
+	knownField := []byte{0x10, 0x2a}
+
+	original := append(append([]byte{}, paddedVarintField...), knownField...)
+
+	var gotKnown uint64
+	unknown, err := MessageEachWithUnknowns(codec.NewBuffer(original), func(fieldNum int32, value Value) FieldAction {
+		if fieldNum == 2 {
+			gotKnown = value.Number
+			return Continue
+		}
+		return SkipField
+	})
+	if err != nil {
+		t.Fatalf("MessageEachWithUnknowns returned error: %v", err)
+	}
+	if gotKnown != 42 {
+		t.Fatalf("expected known field to be 42, got %d", gotKnown)
+	}
+	if len(unknown) != 1 {
+		t.Fatalf("expected 1 unknown field, got %d", len(unknown))
+	}
+
+	out := codec.NewBuffer(nil)
+	for _, field := range unknown {
+		if err := WriteUnknown(out, field); err != nil {
+			t.Fatalf("WriteUnknown returned error: %v", err)
+		}
+	}
+
+	if !bytes.Equal(out.Bytes(), paddedVarintField) {
+		t.Errorf("WriteUnknown did not round-trip the padded varint byte-for-byte: got %v, want %v",
+			out.Bytes(), paddedVarintField)
+	}
+}
+
+func TestMessageEachWithUnknownsStop(t *testing.T) {
+	original := []byte{0x08, 0x01, 0x10, 0x02}
+
+	var seen []int32
+	unknown, err := MessageEachWithUnknowns(codec.NewBuffer(original), func(fieldNum int32, value Value) FieldAction {
+		seen = append(seen, fieldNum)
+		return Stop
+	})
+	if err != nil {
+		t.Fatalf("MessageEachWithUnknowns returned error: %v", err)
+	}
+	if len(seen) != 1 {
+		t.Fatalf("expected iteration to stop after the first field, saw %v", seen)
+	}
+	if len(unknown) != 0 {
+		t.Fatalf("expected no unknown fields to be recorded, got %d", len(unknown))
+	}
+}